@@ -0,0 +1,52 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import "testing"
+
+func TestSplitAcceptsLiteralRunesInQuotes(t *testing.T) {
+	for _, input := range []string{`'héllo'`, `"héllo"`} {
+		got, err := Split(input)
+		if err != nil {
+			t.Fatalf("Split(%q) returned error: %v", input, err)
+		}
+		want := []string{"héllo"}
+		if len(got) != 1 || got[0] != want[0] {
+			t.Errorf("Split(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestSplitRejectsUnknownRuneOutsideQuotes(t *testing.T) {
+	if _, err := Split("héllo"); err == nil {
+		t.Errorf("Split(héllo) with unquoted non-ASCII rune: got no error, want error")
+	}
+}
+
+func TestClassifierCustomWordRunes(t *testing.T) {
+	c := NewClassifier()
+	c.SetWordRunes("abc")
+	c.SetWhitespaceRunes(" ")
+	for _, r := range "abc" {
+		if c.ClassifyRune(r) != charRuneClass {
+			t.Errorf("ClassifyRune(%q) = %v, want charRuneClass", r, c.ClassifyRune(r))
+		}
+	}
+	if c.ClassifyRune('d') != unknownRuneClass {
+		t.Errorf("ClassifyRune('d') = %v, want unknownRuneClass", c.ClassifyRune('d'))
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRegisterOperatorMultiByteRune(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("a →→ b"))
+	tok.RegisterOperator("→→", UnknownToken+100)
+
+	var got []string
+	for {
+		token, err := tok.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() returned error: %v", err)
+		}
+		got = append(got, token.value)
+	}
+
+	want := []string{"a", "→→", "b"}
+	if len(got) != len(want) {
+		t.Fatalf("got tokens %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
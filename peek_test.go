@@ -0,0 +1,99 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPeekDoesNotConsume(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("abc def"))
+	peeked, err := tok.Peek()
+	if err != nil {
+		t.Fatalf("Peek() returned error: %v", err)
+	}
+	if peeked.value != "abc" {
+		t.Fatalf("Peek() = %q, want %q", peeked.value, "abc")
+	}
+	next, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if next.value != "abc" {
+		t.Errorf("Next() after Peek() = %q, want %q", next.value, "abc")
+	}
+}
+
+func TestUnreadReplaysToken(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("abc def"))
+	first, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	tok.Unread(first)
+	replayed, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() after Unread() returned error: %v", err)
+	}
+	if replayed.value != first.value {
+		t.Errorf("Next() after Unread() = %q, want %q", replayed.value, first.value)
+	}
+	second, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if second.value != "def" {
+		t.Errorf("Next() after replay = %q, want %q", second.value, "def")
+	}
+}
+
+func TestTokenizeAll(t *testing.T) {
+	tokens, err := TokenizeAll(strings.NewReader("abc #comment"))
+	if err != nil {
+		t.Fatalf("TokenizeAll() returned error: %v", err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.value)
+	}
+	want := []string{"abc", "#comment"}
+	if len(got) != len(want) {
+		t.Fatalf("got tokens %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLexAll(t *testing.T) {
+	words, err := LexAll(strings.NewReader("one two three"))
+	if err != nil {
+		t.Fatalf("LexAll() returned error: %v", err)
+	}
+	want := []string{"one", "two", "three"}
+	if len(words) != len(want) {
+		t.Fatalf("got words %v, want %v", words, want)
+	}
+	for i := range want {
+		if words[i] != want[i] {
+			t.Errorf("word %d = %q, want %q", i, words[i], want[i])
+		}
+	}
+}
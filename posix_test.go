@@ -0,0 +1,82 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPOSIXLexerSubstitutionTokens(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"$name", "$name"},
+		{"${name}", "${name}"},
+		{"$(cmd)", "$(cmd)"},
+		{"`cmd`", "`cmd`"},
+		{"$((1 + 2))", "$((1 + 2))"},
+	}
+	for _, c := range cases {
+		l := NewPOSIXLexer(strings.NewReader(c.input))
+		got, err := l.Next()
+		if err != nil {
+			t.Fatalf("Next() for %q returned error: %v", c.input, err)
+		}
+		if got != c.want {
+			t.Errorf("Next() for %q = %q, want %q", c.input, got, c.want)
+		}
+	}
+}
+
+func TestSetModeEnablesPOSIXOnTokenizer(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("$name"))
+	tok.SetMode(POSIXMode)
+	token, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if token.tokenType != VarToken || token.value != "$name" {
+		t.Errorf("Next() = (%v, %q), want (VarToken, %q)", token.tokenType, token.value, "$name")
+	}
+}
+
+func TestSetModeComposesWithRegisterOperator(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("$name && echo"))
+	tok.SetMode(POSIXMode)
+	tok.RegisterOperator("&&", UnknownToken+100)
+
+	var got []string
+	for {
+		token, err := tok.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, token.value)
+	}
+
+	want := []string{"$name", "&&", "echo"}
+	if len(got) != len(want) {
+		t.Fatalf("got tokens %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
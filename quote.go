@@ -0,0 +1,114 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import "strings"
+
+// QuoteStyle selects the shell quoting convention used by QuoteWithStyle.
+// Quote and Join always use SingleQuoteStyle.
+type QuoteStyle int
+
+const (
+	// SingleQuoteStyle wraps a word in single quotes, closing the quote,
+	// emitting an escaped literal quote, and reopening it for every
+	// embedded single quote (it's -> 'it'\''s'). This is the default
+	// style used by Quote and Join.
+	SingleQuoteStyle QuoteStyle = iota
+
+	// DoubleQuoteStyle wraps a word in double quotes, backslash-escaping
+	// the runes that remain special inside double quotes ("\$`).
+	DoubleQuoteStyle
+)
+
+// Quote returns a POSIX-shell-safe representation of s using the default
+// quoting style, such that Split(Quote(s)) yields s back as a single
+// word. Runes already in charRunes are left unquoted.
+func Quote(s string) string {
+	return QuoteWithStyle(s, SingleQuoteStyle)
+}
+
+// QuoteWithStyle is like Quote but lets the caller select the quoting
+// convention to match their target shell.
+func QuoteWithStyle(s string, style QuoteStyle) string {
+	if !needsQuoting(s) {
+		return s
+	}
+	switch style {
+	case DoubleQuoteStyle:
+		return quoteDouble(s)
+	default:
+		return quoteSingle(s)
+	}
+}
+
+// Join joins args into a single shell command line such that
+// Split(Join(args)) reproduces args. Each argument is quoted with Quote as
+// needed and separated by a single space.
+func Join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// needsQuoting reports whether s must be quoted to survive a round trip
+// through Split: the empty string, and any string containing a rune
+// outside charRunes.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if !strings.ContainsRune(charRunes, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteSingle wraps s in single quotes, closing and reopening the quote
+// around any embedded single quote.
+func quoteSingle(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		if r == '\'' {
+			b.WriteString(`'\''`)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// quoteDouble wraps s in double quotes, backslash-escaping the runes that
+// remain special within them.
+func quoteDouble(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '$', '`':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
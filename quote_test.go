@@ -0,0 +1,69 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import "testing"
+
+func TestQuoteRoundTrip(t *testing.T) {
+	for _, s := range []string{"plain", "with space", "it's", "", "a\"b", "a$b`c"} {
+		quoted := Quote(s)
+		got, err := Split(quoted)
+		if err != nil {
+			t.Fatalf("Split(Quote(%q)) = Split(%q) returned error: %v", s, quoted, err)
+		}
+		if len(got) != 1 || got[0] != s {
+			t.Errorf("Split(Quote(%q)) = %v, want [%q]", s, got, s)
+		}
+	}
+}
+
+func TestQuoteLeavesPlainWordsUnquoted(t *testing.T) {
+	if got := Quote("plain"); got != "plain" {
+		t.Errorf("Quote(%q) = %q, want %q", "plain", got, "plain")
+	}
+}
+
+func TestQuoteWithStyleDoubleQuote(t *testing.T) {
+	got := QuoteWithStyle(`a"b`, DoubleQuoteStyle)
+	want := `"a\"b"`
+	if got != want {
+		t.Errorf("QuoteWithStyle(%q, DoubleQuoteStyle) = %q, want %q", `a"b`, got, want)
+	}
+	split, err := Split(got)
+	if err != nil {
+		t.Fatalf("Split(%q) returned error: %v", got, err)
+	}
+	if len(split) != 1 || split[0] != `a"b` {
+		t.Errorf("Split(%q) = %v, want [%q]", got, split, `a"b`)
+	}
+}
+
+func TestJoinRoundTrip(t *testing.T) {
+	args := []string{"one", "two three", "it's"}
+	got, err := Split(Join(args))
+	if err != nil {
+		t.Fatalf("Split(Join(%v)) returned error: %v", args, err)
+	}
+	if len(got) != len(args) {
+		t.Fatalf("Split(Join(%v)) = %v, want %v", args, got, args)
+	}
+	for i := range args {
+		if got[i] != args[i] {
+			t.Errorf("arg %d = %q, want %q", i, got[i], args[i])
+		}
+	}
+}
@@ -38,12 +38,34 @@ for token, err := t.Next(); err != nil {
 	// process token
 }
 
+NewTokenizerWithClassifier builds a Tokenizer around a custom Classifier
+instead of the package's default ASCII rune classes, for small DSL
+lexers (CSV-with-quotes, key=value pairs, and the like) that reuse this
+package's state machine with a different set of word, quote, escape,
+comment, and whitespace runes.
+
+Quote and Join are the inverse of Split: they produce a shell-quoted
+string (or command line) that Split will parse back into the original
+arguments.
+
+NewPOSIXLexer, and SetMode(POSIXMode) on a Tokenizer, additionally
+recognise $name, ${...}, $(...), `...`, and $((...)) substitutions as
+structured tokens rather than plain characters.
+
+Peek and Unread give a Tokenizer a single token of lookahead; TokenizeAll
+and LexAll drain a whole stream into a slice in one call.
+
+RegisterKeyword and RegisterOperator let a Tokenizer recognise its own
+reserved words and punctuation operators, for building small
+shell-like or SQL-like DSLs on top of this package's state machine.
+
 */
 import (
 	"bufio"
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 // TokenType is a top-level token classification: A word, space, comment, unknown.
@@ -55,10 +77,22 @@ type runeTokenClass int
 // the internal state used by the lexer state machine
 type lexerState int
 
-// Token is a (type, value) pair representing a lexographical token.
+// Token is a (type, value) pair representing a lexographical token, along
+// with the position in the input stream where it began.
 type Token struct {
 	tokenType TokenType
 	value     string
+	line      int
+	col       int
+	offset    int
+}
+
+// Position returns the line, column, and byte offset at which the token's
+// first rune was read from the input stream. Lines and columns are
+// 1-indexed; offset is a 0-indexed byte count. If the token did not
+// originate from a stream with position tracking, all three are zero.
+func (a *Token) Position() (line, col, offset int) {
+	return a.line, a.col, a.offset
 }
 
 // Equal reports whether tokens a, and b, are equal.
@@ -102,6 +136,35 @@ const (
 	WordToken    TokenType = iota
 	SpaceToken   TokenType = iota
 	CommentToken TokenType = iota
+
+	// VarToken is a POSIX-mode token for a variable substitution, such as
+	// $name or ${name}.
+	VarToken TokenType = iota
+
+	// CmdSubToken is a POSIX-mode token for a command substitution, such
+	// as $(cmd) or `cmd`.
+	CmdSubToken TokenType = iota
+
+	// ArithToken is a POSIX-mode token for an arithmetic substitution,
+	// such as $((1 + 2)).
+	ArithToken TokenType = iota
+)
+
+// Mode selects which lexer rules scanStream applies.
+type Mode int
+
+const (
+	// ASCIIMode is the default lexer mode. '$' and '`' are ordinary
+	// unknown runes rather than the start of a substitution, matching
+	// the historical shlex behavior for those two runes.
+	ASCIIMode Mode = iota
+
+	// POSIXMode additionally recognises $name, ${...}, $(...), `...`,
+	// and $((...)). A substitution that begins a token is returned as a
+	// structured VarToken, CmdSubToken, or ArithToken; one that appears
+	// mid-word or inside an escaping-double-quoted string is scanned as a
+	// unit but its literal text is kept in the surrounding WordToken.
+	POSIXMode Mode = iota
 )
 
 // Lexer state machine states
@@ -132,33 +195,78 @@ const (
 	initialTokenCapacity int = 100
 )
 
-// tokenClassifier is used for classifying rune characters
-type tokenClassifier struct {
+// Classifier is used for classifying rune characters into rune token
+// classes, and can be customized to recognize a different set of word,
+// quote, escape, comment, and whitespace runes than the package default.
+// A rune with no assigned class (unknownRuneClass) is rejected outside of
+// a quoted string, but is taken as ordinary literal content inside one:
+// a quote should never force the caller to enumerate every rune that may
+// appear within it.
+type Classifier struct {
 	typeMap map[rune]runeTokenClass
 }
 
-func addRuneClass(typeMap *map[rune]runeTokenClass, runes string, tokenType runeTokenClass) {
+// NewClassifier creates a new, empty classifier. Every rune is initially
+// classified as unknownRuneClass; use the Set* methods to assign rune
+// classes before passing the classifier to NewTokenizerWithClassifier.
+func NewClassifier() *Classifier {
+	return &Classifier{
+		typeMap: map[rune]runeTokenClass{}}
+}
+
+func (c *Classifier) addRuneClass(runes string, tokenType runeTokenClass) {
 	for _, runeChar := range runes {
-		(*typeMap)[runeChar] = tokenType
+		c.typeMap[runeChar] = tokenType
 	}
 }
 
+// SetWordRunes classifies runes as ordinary word characters that may
+// appear unquoted in a token.
+func (c *Classifier) SetWordRunes(runes string) {
+	c.addRuneClass(runes, charRuneClass)
+}
+
+// SetQuoteRunes classifies escaping as the set of quote runes that support
+// backslash-escaping of their contents (such as the shell's double quote),
+// and nonEscaping as the set that take their contents literally (such as
+// the shell's single quote).
+func (c *Classifier) SetQuoteRunes(escaping, nonEscaping string) {
+	c.addRuneClass(escaping, escapingQuoteRuneClass)
+	c.addRuneClass(nonEscaping, nonEscapingQuoteRuneClass)
+}
+
+// SetEscapeRunes classifies runes as escape characters, which cause the
+// rune that follows them to be taken literally.
+func (c *Classifier) SetEscapeRunes(runes string) {
+	c.addRuneClass(runes, escapeRuneClass)
+}
+
+// SetCommentRunes classifies runes as comment characters, which cause the
+// remainder of the line to be classified as a CommentToken.
+func (c *Classifier) SetCommentRunes(runes string) {
+	c.addRuneClass(runes, commentRuneClass)
+}
+
+// SetWhitespaceRunes classifies runes as whitespace, which separates
+// tokens from one another and is otherwise discarded.
+func (c *Classifier) SetWhitespaceRunes(runes string) {
+	c.addRuneClass(runes, spaceRuneClass)
+}
+
 // NewDefaultClassifier creates a new classifier for ASCII characters.
-func NewDefaultClassifier() *tokenClassifier {
-	typeMap := map[rune]runeTokenClass{}
-	addRuneClass(&typeMap, charRunes, charRuneClass)
-	addRuneClass(&typeMap, spaceRunes, spaceRuneClass)
-	addRuneClass(&typeMap, escapingQuoteRunes, escapingQuoteRuneClass)
-	addRuneClass(&typeMap, nonEscapingQuoteRunes, nonEscapingQuoteRuneClass)
-	addRuneClass(&typeMap, escapeRunes, escapeRuneClass)
-	addRuneClass(&typeMap, commentRunes, commentRuneClass)
-	return &tokenClassifier{
-		typeMap: typeMap}
+func NewDefaultClassifier() *Classifier {
+	c := NewClassifier()
+	c.SetWordRunes(charRunes)
+	c.SetWhitespaceRunes(spaceRunes)
+	c.SetQuoteRunes(escapingQuoteRunes, nonEscapingQuoteRunes)
+	c.SetEscapeRunes(escapeRunes)
+	c.SetCommentRunes(commentRunes)
+	return c
 }
 
 // ClassifyRune classifiees a rune
-func (classifier *tokenClassifier) ClassifyRune(runeVal rune) runeTokenClass {
-	return classifier.typeMap[runeVal]
+func (c *Classifier) ClassifyRune(runeVal rune) runeTokenClass {
+	return c.typeMap[runeVal]
 }
 
 // Lexer turns an input stream into a sequence of tokens. Whitespace and comments are skipped.
@@ -173,6 +281,16 @@ func NewLexer(r io.Reader) *Lexer {
 	return &Lexer{tokenizer: tokenizer}
 }
 
+// NewPOSIXLexer creates a new lexer from an input stream whose tokenizer
+// runs in POSIXMode, so that $name, ${...}, $(...), `...`, and $((...))
+// are recognised as structured VarToken, CmdSubToken, and ArithToken
+// tokens rather than plain characters.
+func NewPOSIXLexer(r io.Reader) *Lexer {
+	tokenizer := NewTokenizer(r)
+	tokenizer.SetMode(POSIXMode)
+	return &Lexer{tokenizer: tokenizer}
+}
+
 // Next returns the next word, or an error. If there are no more words,
 // the error will be io.EOF.
 func (l *Lexer) Next() (string, error) {
@@ -184,7 +302,7 @@ func (l *Lexer) Next() (string, error) {
 			return "", err
 		}
 		switch token.tokenType {
-		case WordToken:
+		case WordToken, VarToken, CmdSubToken, ArithToken:
 			{
 				return token.value, nil
 			}
@@ -203,17 +321,281 @@ func (l *Lexer) Next() (string, error) {
 
 // Tokenizer turns an input stream into a sequence of typed tokens
 type Tokenizer struct {
-	input      *bufio.Reader
-	classifier *tokenClassifier
+	input        *bufio.Reader
+	classifier   *Classifier
+	mode         Mode
+	line         int
+	col          int
+	offset       int
+	lastLine     int
+	lastCol      int
+	lastOffset   int
+	havePushback bool
+	pushback     *Token
+	pushbackErr  error
+
+	keywords        map[string]TokenType
+	keywordsFold    map[string]TokenType
+	caseInsensitive bool
+
+	operators      map[string]TokenType
+	maxOperatorLen int
 }
 
 // NewTokenizer creates a new tokenizer from an input stream.
 func NewTokenizer(r io.Reader) *Tokenizer {
+	return NewTokenizerWithClassifier(r, NewDefaultClassifier())
+}
+
+// NewTokenizerWithClassifier creates a new tokenizer from an input stream,
+// using the given classifier instead of the package's default ASCII rune
+// classes. This lets callers build small DSL lexers (CSV-with-quotes,
+// key=value pairs, Dockerfile-style directives, and the like) on top of
+// the same state machine without forking the package.
+func NewTokenizerWithClassifier(r io.Reader, c *Classifier) *Tokenizer {
 	input := bufio.NewReader(r)
-	classifier := NewDefaultClassifier()
 	return &Tokenizer{
 		input:      input,
-		classifier: classifier}
+		classifier: c,
+		line:       1,
+		col:        1}
+}
+
+// readRune reads the next rune from the input, advancing the tokenizer's
+// line/column/offset tracker. It remembers enough of the pre-read position
+// to let a single subsequent unreadRune roll the tracker back.
+func (t *Tokenizer) readRune() (rune, error) {
+	nextRune, size, err := t.input.ReadRune()
+	t.lastLine, t.lastCol, t.lastOffset = t.line, t.col, t.offset
+	if err != nil {
+		return nextRune, err
+	}
+	t.offset += size
+	if nextRune == '\n' {
+		t.line++
+		t.col = 1
+	} else {
+		t.col++
+	}
+	return nextRune, nil
+}
+
+// unreadRune undoes the most recent readRune, including its effect on the
+// line/column/offset tracker. It must only be called once per readRune.
+func (t *Tokenizer) unreadRune() error {
+	if err := t.input.UnreadRune(); err != nil {
+		return err
+	}
+	t.line, t.col, t.offset = t.lastLine, t.lastCol, t.lastOffset
+	return nil
+}
+
+// RegisterKeyword registers word so that a WordToken whose value exactly
+// matches it is retyped to tt once scanStream finishes reading it. Word
+// keywords are matched case-sensitively unless SetCaseInsensitive(true)
+// has been called. This turns a Tokenizer into a usable front-end for
+// small command-language parsers (think git-style subcommand DSLs)
+// without requiring callers to post-process the string slice from Split.
+func (t *Tokenizer) RegisterKeyword(word string, tt TokenType) {
+	if t.keywords == nil {
+		t.keywords = map[string]TokenType{}
+		t.keywordsFold = map[string]TokenType{}
+	}
+	t.keywords[word] = tt
+	t.keywordsFold[strings.ToLower(word)] = tt
+}
+
+// SetCaseInsensitive controls whether RegisterKeyword lookups ignore
+// case. It is case-sensitive by default.
+func (t *Tokenizer) SetCaseInsensitive(caseInsensitive bool) {
+	t.caseInsensitive = caseInsensitive
+}
+
+// SetMode selects the lexer rules scanStream applies, as described by
+// ASCIIMode and POSIXMode. A Tokenizer runs in ASCIIMode by default; use
+// SetMode to run POSIX substitution parsing on a *Tokenizer directly,
+// for example to combine it with Peek, Unread, RegisterKeyword, or
+// RegisterOperator rather than going through the word-level *Lexer.
+func (t *Tokenizer) SetMode(mode Mode) {
+	t.mode = mode
+}
+
+func (t *Tokenizer) lookupKeyword(word string) (TokenType, bool) {
+	if t.caseInsensitive {
+		tt, ok := t.keywordsFold[strings.ToLower(word)]
+		return tt, ok
+	}
+	tt, ok := t.keywords[word]
+	return tt, ok
+}
+
+// RegisterOperator registers runes (for example "==", "&&", or a single
+// character like ";") as an operator that scanStream should recognise on
+// punctuation it would otherwise reject as an unknown rune, emitting a
+// token of type tt whose value is the operator's literal text. Operator
+// recognition fires in startState and inWordState; the longest
+// registered operator matching the input wins.
+func (t *Tokenizer) RegisterOperator(runes string, tt TokenType) {
+	if t.operators == nil {
+		t.operators = map[string]TokenType{}
+	}
+	t.operators[runes] = tt
+	if n := utf8.RuneCountInString(runes); n > t.maxOperatorLen {
+		t.maxOperatorLen = n
+	}
+}
+
+// peekRunes peeks the next n runes of the input without consuming them,
+// decoding the UTF-8 byte buffer rune-by-rune so that multi-byte runes
+// are never split. It reports false if fewer than n runes are available.
+func (t *Tokenizer) peekRunes(n int) ([]rune, bool) {
+	buf, _ := t.input.Peek(n * utf8.UTFMax)
+	runes := make([]rune, 0, n)
+	for len(buf) > 0 && len(runes) < n {
+		r, size := utf8.DecodeRune(buf)
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		runes = append(runes, r)
+		buf = buf[size:]
+	}
+	if len(runes) < n {
+		return nil, false
+	}
+	return runes, true
+}
+
+// matchOperator looks for the longest registered operator beginning with
+// first, peeking ahead in the input without consuming runes that turn out
+// not to be part of a match. On a match it consumes the remaining runes
+// of the operator (first has already been read by the caller) so the
+// tokenizer's line/column/offset tracking stays accurate.
+func (t *Tokenizer) matchOperator(first rune) (string, TokenType, bool) {
+	if len(t.operators) == 0 {
+		return "", UnknownToken, false
+	}
+	for n := t.maxOperatorLen; n >= 1; n-- {
+		candidate := string(first)
+		if n > 1 {
+			rest, ok := t.peekRunes(n - 1)
+			if !ok {
+				continue
+			}
+			candidate += string(rest)
+		}
+		if tt, ok := t.operators[candidate]; ok {
+			for i := 0; i < n-1; i++ {
+				t.readRune()
+			}
+			return candidate, tt, true
+		}
+	}
+	return "", UnknownToken, false
+}
+
+// scanBalanced consumes runes, tracking nested open/close pairs, until
+// depth (the count of open runes already consumed, including the one
+// immediately before the call) returns to zero. This lets command,
+// brace, and arithmetic substitutions contain further nested
+// substitutions of the same kind without losing track of where they
+// close. The returned runes include the final close rune.
+func (t *Tokenizer) scanBalanced(open, close rune, depth int) ([]rune, error) {
+	var body []rune
+	for depth > 0 {
+		r, err := t.readRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("EOF found when expecting closing %q at line %d, col %d (offset %d)", close, t.line, t.col, t.offset)
+			}
+			return nil, err
+		}
+		switch r {
+		case open:
+			depth++
+		case close:
+			depth--
+		}
+		body = append(body, r)
+	}
+	return body, nil
+}
+
+// scanBacktick consumes runes up to and including the closing backtick of
+// a `...` command substitution that began with the backtick already read.
+func (t *Tokenizer) scanBacktick() ([]rune, error) {
+	text := []rune{'`'}
+	for {
+		r, err := t.readRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("EOF found when expecting closing backtick at line %d, col %d (offset %d)", t.line, t.col, t.offset)
+			}
+			return nil, err
+		}
+		text = append(text, r)
+		if r == '`' {
+			return text, nil
+		}
+	}
+}
+
+// scanDollar consumes a $name, ${...}, $(...), or $((...)) substitution
+// immediately following a '$' that has already been read, returning its
+// full literal text and the structured TokenType it represents.
+func (t *Tokenizer) scanDollar() ([]rune, TokenType, error) {
+	text := []rune{'$'}
+	r, err := t.readRune()
+	if err != nil {
+		if err == io.EOF {
+			// No substitution was ever opened, so a trailing bare '$'
+			// (e.g. a shell prompt character at end of input) is just
+			// literal text, not an unclosed substitution.
+			return text, WordToken, nil
+		}
+		return nil, UnknownToken, err
+	}
+	switch r {
+	case '(':
+		text = append(text, r)
+		r2, err2 := t.readRune()
+		if err2 == nil && r2 == '(' {
+			text = append(text, r2)
+			body, err := t.scanBalanced('(', ')', 2)
+			if err != nil {
+				return nil, UnknownToken, err
+			}
+			return append(text, body...), ArithToken, nil
+		}
+		if err2 == nil {
+			t.unreadRune()
+		}
+		body, err := t.scanBalanced('(', ')', 1)
+		if err != nil {
+			return nil, UnknownToken, err
+		}
+		return append(text, body...), CmdSubToken, nil
+	case '{':
+		text = append(text, r)
+		body, err := t.scanBalanced('{', '}', 1)
+		if err != nil {
+			return nil, UnknownToken, err
+		}
+		return append(text, body...), VarToken, nil
+	default:
+		t.unreadRune()
+		for {
+			r, err := t.readRune()
+			if err != nil {
+				break
+			}
+			if t.classifier.ClassifyRune(r) != charRuneClass {
+				t.unreadRune()
+				break
+			}
+			text = append(text, r)
+		}
+		return text, VarToken, nil
+	}
 }
 
 // scanStream scans the stream for the next token using the internal state machine.
@@ -226,10 +608,15 @@ func (t *Tokenizer) scanStream() (*Token, error) {
 		nextRune     rune
 		nextRuneType runeTokenClass
 		err          error
+		tokenLine    int
+		tokenCol     int
+		tokenOffset  int
+		haveStart    bool
 	)
 SCAN:
 	for {
-		nextRune, _, err = t.input.ReadRune()
+		startLine, startCol, startOffset := t.line, t.col, t.offset
+		nextRune, err = t.readRune()
 		nextRuneType = t.classifier.ClassifyRune(nextRune)
 		if err != nil {
 			if err == io.EOF {
@@ -239,6 +626,45 @@ SCAN:
 				return nil, err
 			}
 		}
+		// Whitespace read while still in startState is skipped rather
+		// than starting a token, so it must not latch the start
+		// position; haveStart only locks in once a rune actually
+		// begins consuming the token.
+		if !haveStart && nextRuneType != eofRuneClass && !(state == startState && nextRuneType == spaceRuneClass) {
+			tokenLine, tokenCol, tokenOffset = startLine, startCol, startOffset
+			haveStart = true
+		}
+		if t.mode == POSIXMode && nextRuneType != eofRuneClass && (nextRune == '$' || nextRune == '`') {
+			switch state {
+			case startState, inWordState, quotingEscapingState:
+				var subText []rune
+				var subType TokenType
+				var serr error
+				if nextRune == '$' {
+					subText, subType, serr = t.scanDollar()
+				} else {
+					subText, serr = t.scanBacktick()
+					subType = CmdSubToken
+				}
+				if serr != nil {
+					return nil, serr
+				}
+				if state == startState {
+					return &Token{
+						tokenType: subType,
+						value:     string(subText),
+						line:      tokenLine,
+						col:       tokenCol,
+						offset:    tokenOffset}, nil
+				}
+				// Mid-word or inside a double-quoted string: the
+				// substitution's literal text becomes part of the
+				// surrounding word rather than its own token.
+				tokenType = WordToken
+				value = append(value, subText...)
+				continue SCAN
+			}
+		}
 		switch state {
 		case startState: // no runes read yet
 			{
@@ -278,7 +704,15 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						if op, opType, ok := t.matchOperator(nextRune); ok {
+							return &Token{
+								tokenType: opType,
+								value:     op,
+								line:      tokenLine,
+								col:       tokenCol,
+								offset:    tokenOffset}, nil
+						}
+						return nil, fmt.Errorf("Uknown rune: %v at line %d, col %d (offset %d)", nextRune, startLine, startCol, startOffset)
 					}
 				}
 			}
@@ -295,7 +729,7 @@ SCAN:
 					}
 				case spaceRuneClass:
 					{
-						t.input.UnreadRune()
+						t.unreadRune()
 						break SCAN
 					}
 				case escapingQuoteRuneClass:
@@ -312,7 +746,18 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						if op, opType, ok := t.matchOperator(nextRune); ok {
+							t.pushback = &Token{
+								tokenType: opType,
+								value:     op,
+								line:      startLine,
+								col:       startCol,
+								offset:    startOffset}
+							t.pushbackErr = nil
+							t.havePushback = true
+							break SCAN
+						}
+						return nil, fmt.Errorf("Uknown rune: %v at line %d, col %d (offset %d)", nextRune, startLine, startCol, startOffset)
 					}
 				}
 			}
@@ -321,7 +766,7 @@ SCAN:
 				switch nextRuneType {
 				case eofRuneClass:
 					{
-						err = fmt.Errorf("EOF found after escape character")
+						err = fmt.Errorf("EOF found after escape character at line %d, col %d (offset %d)", t.line, t.col, t.offset)
 						break SCAN
 					}
 				case charRuneClass, spaceRuneClass, escapingQuoteRuneClass, nonEscapingQuoteRuneClass, escapeRuneClass, commentRuneClass:
@@ -331,7 +776,7 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						return nil, fmt.Errorf("Uknown rune: %v at line %d, col %d (offset %d)", nextRune, startLine, startCol, startOffset)
 					}
 				}
 			}
@@ -340,7 +785,7 @@ SCAN:
 				switch nextRuneType {
 				case eofRuneClass:
 					{
-						err = fmt.Errorf("EOF found after escape character")
+						err = fmt.Errorf("EOF found after escape character at line %d, col %d (offset %d)", t.line, t.col, t.offset)
 						break SCAN
 					}
 				case charRuneClass, spaceRuneClass, escapingQuoteRuneClass, nonEscapingQuoteRuneClass, escapeRuneClass, commentRuneClass:
@@ -350,7 +795,10 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						// Still inside the quoted string, so an
+						// unclassified rune is literal content.
+						state = quotingEscapingState
+						value = append(value, nextRune)
 					}
 				}
 			}
@@ -359,7 +807,7 @@ SCAN:
 				switch nextRuneType {
 				case eofRuneClass:
 					{
-						err = fmt.Errorf("EOF found when expecting closing quote")
+						err = fmt.Errorf("EOF found when expecting closing quote at line %d, col %d (offset %d)", t.line, t.col, t.offset)
 						break SCAN
 					}
 				case charRuneClass, spaceRuneClass, nonEscapingQuoteRuneClass, commentRuneClass:
@@ -376,7 +824,10 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						// A quoted string should never reject a
+						// character; anything not otherwise
+						// classified is literal quoted content.
+						value = append(value, nextRune)
 					}
 				}
 			}
@@ -385,7 +836,7 @@ SCAN:
 				switch nextRuneType {
 				case eofRuneClass:
 					{
-						err = fmt.Errorf("EOF found when expecting closing quote")
+						err = fmt.Errorf("EOF found when expecting closing quote at line %d, col %d (offset %d)", t.line, t.col, t.offset)
 						break SCAN
 					}
 				case charRuneClass, spaceRuneClass, escapingQuoteRuneClass, escapeRuneClass, commentRuneClass:
@@ -398,7 +849,10 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						// A quoted string should never reject a
+						// character; anything not otherwise
+						// classified is literal quoted content.
+						value = append(value, nextRune)
 					}
 				}
 			}
@@ -424,7 +878,7 @@ SCAN:
 					}
 				default:
 					{
-						return nil, fmt.Errorf("Uknown rune: %v", nextRune)
+						return nil, fmt.Errorf("Uknown rune: %v at line %d, col %d (offset %d)", nextRune, startLine, startCol, startOffset)
 					}
 				}
 			}
@@ -436,15 +890,86 @@ SCAN:
 	}
 	token := &Token{
 		tokenType: tokenType,
-		value:     string(value)}
+		value:     string(value),
+		line:      tokenLine,
+		col:       tokenCol,
+		offset:    tokenOffset}
+	if token.tokenType == WordToken {
+		if tt, ok := t.lookupKeyword(token.value); ok {
+			token.tokenType = tt
+		}
+	}
 	return token, err
 }
 
 // Next returns the next token in the stream.
 func (t *Tokenizer) Next() (*Token, error) {
+	if t.havePushback {
+		tok, err := t.pushback, t.pushbackErr
+		t.pushback, t.pushbackErr, t.havePushback = nil, nil, false
+		return tok, err
+	}
 	return t.scanStream()
 }
 
+// Peek returns the next token without consuming it: it buffers the token
+// (and any error) in a one-slot pushback that scanStream is bypassed for,
+// so that the following call to Next or Peek returns the same result.
+// This lets recursive-descent parsers built on top of a Tokenizer look
+// ahead one token without reimplementing buffering themselves.
+func (t *Tokenizer) Peek() (*Token, error) {
+	if !t.havePushback {
+		t.pushback, t.pushbackErr = t.scanStream()
+		t.havePushback = true
+	}
+	return t.pushback, t.pushbackErr
+}
+
+// Unread pushes tok back onto the tokenizer so that the next call to Next
+// or Peek returns it again. It supports a single token of lookahead; a
+// second call before the first is consumed replaces it.
+func (t *Tokenizer) Unread(tok *Token) {
+	t.pushback, t.pushbackErr, t.havePushback = tok, nil, true
+}
+
+// TokenizeAll drains r through a Tokenizer created by NewTokenizer,
+// returning every token (including comment tokens) it produces. The
+// Tokenizer runs in ASCIIMode, so no substitution tokens are produced. An io.EOF from the stream ends collection cleanly, the same
+// way Split's io.EOF is translated into a nil error.
+func TokenizeAll(r io.Reader) ([]Token, error) {
+	t := NewTokenizer(r)
+	tokens := make([]Token, 0)
+	for {
+		token, err := t.Next()
+		if err != nil {
+			if err == io.EOF {
+				return tokens, nil
+			}
+			return tokens, err
+		}
+		tokens = append(tokens, *token)
+	}
+}
+
+// LexAll drains r through a Lexer, returning every word it produces
+// (comments are skipped, as in Lexer.Next). An io.EOF from the stream
+// ends collection cleanly, the same way Split's io.EOF is translated
+// into a nil error.
+func LexAll(r io.Reader) ([]string, error) {
+	l := NewLexer(r)
+	words := make([]string, 0)
+	for {
+		word, err := l.Next()
+		if err != nil {
+			if err == io.EOF {
+				return words, nil
+			}
+			return words, err
+		}
+		words = append(words, word)
+	}
+}
+
 // Split partitions a string into a slice of strings.
 func Split(s string) ([]string, error) {
 	l := NewLexer(strings.NewReader(s))
@@ -0,0 +1,96 @@
+/*
+Copyright 2012 Google Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenPositionSkipsLeadingWhitespace(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("  abc"))
+	token, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if token.value != "abc" {
+		t.Fatalf("got value %q, want %q", token.value, "abc")
+	}
+	line, col, offset := token.Position()
+	if line != 1 || col != 3 || offset != 2 {
+		t.Errorf("Position() = (%d, %d, %d), want (1, 3, 2)", line, col, offset)
+	}
+}
+
+func TestTokenPositionAcrossMultipleTokens(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("abc def ghi"))
+	wantPositions := []struct {
+		value          string
+		line, col, off int
+	}{
+		{"abc", 1, 1, 0},
+		{"def", 1, 5, 4},
+		{"ghi", 1, 9, 8},
+	}
+	for _, want := range wantPositions {
+		token, err := tok.Next()
+		if err != nil {
+			t.Fatalf("Next() returned error: %v", err)
+		}
+		if token.value != want.value {
+			t.Fatalf("got value %q, want %q", token.value, want.value)
+		}
+		line, col, offset := token.Position()
+		if line != want.line || col != want.col || offset != want.off {
+			t.Errorf("Position() for %q = (%d, %d, %d), want (%d, %d, %d)",
+				want.value, line, col, offset, want.line, want.col, want.off)
+		}
+	}
+}
+
+func TestTokenPositionAcrossLines(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("one\ntwo"))
+	if _, err := tok.Next(); err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	token, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if token.value != "two" {
+		t.Fatalf("got value %q, want %q", token.value, "two")
+	}
+	line, col, offset := token.Position()
+	if line != 2 || col != 1 || offset != 4 {
+		t.Errorf("Position() = (%d, %d, %d), want (2, 1, 4)", line, col, offset)
+	}
+}
+
+func TestUnreadRestoresOffset(t *testing.T) {
+	tok := NewTokenizer(strings.NewReader("abc def"))
+	if _, err := tok.Next(); err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	token, err := tok.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	_, _, offset := token.Position()
+	if offset != 4 {
+		t.Errorf("Position() offset = %d, want 4 (unreading the separating space must roll offset back)", offset)
+	}
+}